@@ -2,11 +2,13 @@
 // Use of this source code is governed by the MIT License
 // that can be found in the LICENSE file.
 
-// Package backoff provides a Fibonacci backoff implementation.
+// Package backoff provides a backoff implementation with pluggable strategies,
+// defaulting to the Fibonacci suite.
 package backoff
 
 import (
 	"context"
+	"math/rand"
 	"sync"
 	"time"
 )
@@ -34,8 +36,9 @@ func fibonacci() funcAlgorithm {
 type Func func(context.Context) error
 
 // Do guarantees to execute at least once f if ctx is not already cancelled.
-// As long as f return in success and the context not done, BackOff will continue to call it,
-// with sleep duration based the Fibonacci suite and the BackOff's interval.
+// As long as f returns in success or a RetryableError and the context is not done,
+// BackOff will continue to call it, with sleep duration based the Fibonacci suite
+// and the BackOff's interval. Any other error is terminal and returned immediately.
 func Do(ctx context.Context, f Func) (int, error) {
 	return New(ctx).Do(f)
 }
@@ -52,6 +55,8 @@ func DoUntil(ctx context.Context, t time.Time, f Func) (int, error) {
 
 // Retry retries the function f until it does not return error or BackOff stops.
 // f is guaranteed to be run at least once, unless the context is already cancelled.
+// A non-retryable error (one not wrapped with RetryableError) is terminal and returned
+// immediately, without waiting for the maximum attempt or the deadline to be exceeded.
 func Retry(ctx context.Context, f Func) (int, error) {
 	return New(ctx).Retry(f)
 }
@@ -70,7 +75,8 @@ func RetryUntil(ctx context.Context, t time.Time, f Func) (int, error) {
 type Retryer interface {
 	// Attempt returns the current number of attempt.
 	Attempt() int
-	// Do executes the given function every "fib tick" as long as it is successful.
+	// Do executes the given function every "fib tick" as long as it is successful or
+	// returns a RetryableError. Any other error is terminal and returned immediately.
 	// A context cancelled, a deadline or maximum attempt exceeded can also break the loop.
 	Do(f Func) (int, error)
 	// Reset resets to initial state.
@@ -78,13 +84,40 @@ type Retryer interface {
 	// Retry executes the given function every "fib tick" as long as it is failed.
 	// A context cancelled, a deadline or maximum attempt exceeded can also break the loop.
 	Retry(f Func) (int, error)
+	// WithClock sets the Clock used to sleep and evaluate deadlines, in place of the
+	// real one. Mainly useful to make tests deterministic and instantaneous.
+	WithClock(c Clock) Retryer
 	// WithDeadline creates a copy of the current Backoff to defines a new context
 	// with the deadline adjusted to be no later than t.
 	WithDeadline(t time.Time) Retryer
 	// WithInterval sets the time interval between two try with the value of d.
 	WithInterval(d time.Duration) Retryer
+	// WithJitter adds up to ±d of random noise to each computed delay,
+	// to avoid a thundering herd when many Backoff share the same schedule.
+	WithJitter(d time.Duration) Retryer
+	// WithJitterPercent adds up to ±p% of random noise to each computed delay.
+	// It takes precedence over WithJitter when both are set.
+	WithJitterPercent(p uint64) Retryer
 	// WithMaxAttempt sets the maximum number of attempt to n.
 	WithMaxAttempt(n int) Retryer
+	// WithMaxInterval caps the delay between two attempts to d, once the computed
+	// delay would otherwise exceed it; jitter, if any, is added after the cap.
+	// Recommended when retrying without a maximum attempt or deadline with the default
+	// Fibonacci suite, whose delay grows unbounded. A Strategy still ends the loop once
+	// it reports no more delay, as NewExponential and NewFibonacci do when their delay
+	// overflows, whether a cap is set or not.
+	WithMaxInterval(d time.Duration) Retryer
+	// WithNotify sets a callback invoked after each attempt followed by another one,
+	// before sleeping, with the attempt index, the elapsed time since the first attempt,
+	// the delay before the next attempt and the error returned by the last attempt
+	// (nil when Do goes on after a success). It is not called after the last attempt.
+	WithNotify(fn func(attempt int, elapsed, next time.Duration, err error)) Retryer
+	// WithRand sets the source of randomness used by WithJitter and WithJitterPercent,
+	// allowing deterministic tests when seeded with a fixed value.
+	WithRand(r *rand.Rand) Retryer
+	// WithStrategy sets the backoff algorithm used to compute the delay between two attempts,
+	// in place of the default Fibonacci suite.
+	WithStrategy(s Strategy) Retryer
 }
 
 // New returns a new instance of Backoff.
@@ -102,21 +135,30 @@ func newBackoff() *Backoff {
 		interval: DefaultInterval,
 		err:      make(chan error),
 		fib:      fibonacci(),
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		clock:    realClock{},
 	}
 }
 
 // Backoff is a time.Duration and an attempt counter.
 // It provides means to do and retry something based on the Fibonacci suite as trigger.
 type Backoff struct {
-	ctx    context.Context
-	cancel context.CancelFunc
-	err    chan error
-	fib    funcAlgorithm
+	ctx      context.Context
+	cancel   context.CancelFunc
+	err      chan error
+	fib      funcAlgorithm
+	strategy Strategy
+	rnd      *rand.Rand
+	clock    Clock
+	notifyFn func(attempt int, elapsed, next time.Duration, err error)
 
 	attempt,
 	maxAttempt int
-	interval time.Duration
-	mu       sync.RWMutex
+	interval,
+	jitter,
+	maxInterval time.Duration
+	jitterPercent uint64
+	mu            sync.RWMutex
 }
 
 // Attempt implements the Retryer interface.
@@ -140,6 +182,7 @@ func (b *Backoff) Reset() {
 	b.mu.Lock()
 	b.attempt = 0
 	b.fib = fibonacci()
+	b.interval = DefaultInterval
 	b.mu.Unlock()
 }
 
@@ -152,10 +195,28 @@ func (b *Backoff) Retry(f Func) (int, error) {
 	return b.done()
 }
 
+// WithClock implements the Retryer interface.
+func (b *Backoff) WithClock(c Clock) Retryer {
+	if c != nil {
+		b.mu.Lock()
+		b.clock = c
+		b.mu.Unlock()
+	}
+	return b
+}
+
 // WithDeadline implements the Retryer interface.
 func (b *Backoff) WithDeadline(t time.Time) Retryer {
 	b2 := b.copy()
-	b2.ctx, b2.cancel = context.WithDeadline(b.ctx, t)
+	ctx, cancel := context.WithCancel(b.ctx)
+	b2.ctx, b2.cancel = ctx, cancel
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-b2.clock.After(t.Sub(b2.clock.Now())):
+			cancel()
+		}
+	}()
 	return b2
 }
 
@@ -169,6 +230,24 @@ func (b *Backoff) WithInterval(d time.Duration) Retryer {
 	return b
 }
 
+// WithJitter implements the Retryer interface.
+func (b *Backoff) WithJitter(d time.Duration) Retryer {
+	if d > 0 {
+		b.mu.Lock()
+		b.jitter = d
+		b.mu.Unlock()
+	}
+	return b
+}
+
+// WithJitterPercent implements the Retryer interface.
+func (b *Backoff) WithJitterPercent(p uint64) Retryer {
+	b.mu.Lock()
+	b.jitterPercent = p
+	b.mu.Unlock()
+	return b
+}
+
 // WithMaxAttempt implements the Retryer interface.
 func (b *Backoff) WithMaxAttempt(n int) Retryer {
 	if n > -1 {
@@ -179,6 +258,46 @@ func (b *Backoff) WithMaxAttempt(n int) Retryer {
 	return b
 }
 
+// WithMaxInterval implements the Retryer interface.
+func (b *Backoff) WithMaxInterval(d time.Duration) Retryer {
+	if d > 0 {
+		b.mu.Lock()
+		b.maxInterval = d
+		b.mu.Unlock()
+	}
+	return b
+}
+
+// WithNotify implements the Retryer interface.
+func (b *Backoff) WithNotify(fn func(attempt int, elapsed, next time.Duration, err error)) Retryer {
+	if fn != nil {
+		b.mu.Lock()
+		b.notifyFn = fn
+		b.mu.Unlock()
+	}
+	return b
+}
+
+// WithRand implements the Retryer interface.
+func (b *Backoff) WithRand(r *rand.Rand) Retryer {
+	if r != nil {
+		b.mu.Lock()
+		b.rnd = r
+		b.mu.Unlock()
+	}
+	return b
+}
+
+// WithStrategy implements the Retryer interface.
+func (b *Backoff) WithStrategy(s Strategy) Retryer {
+	if s != nil {
+		b.mu.Lock()
+		b.strategy = s
+		b.mu.Unlock()
+	}
+	return b
+}
+
 // copy copies the Backoff to create a new one with the same behavior.
 // It also takes care of the underlying mutex.
 func (b *Backoff) copy() *Backoff {
@@ -186,6 +305,13 @@ func (b *Backoff) copy() *Backoff {
 	b.mu.Lock()
 	b2.interval = b.interval
 	b2.maxAttempt = b.maxAttempt
+	b2.strategy = b.strategy
+	b2.jitter = b.jitter
+	b2.jitterPercent = b.jitterPercent
+	b2.maxInterval = b.maxInterval
+	b2.rnd = rand.New(rand.NewSource(b.rnd.Int63()))
+	b2.clock = b.clock
+	b2.notifyFn = b.notifyFn
 	b.mu.Unlock()
 	return b2
 }
@@ -216,7 +342,8 @@ func (b *Backoff) next() error {
 
 // run runs the Retryer strategy by using f as job to do and retry as mode.
 func (b *Backoff) run(f Func, retry bool) {
-	var err, rrr error
+	var err error
+	start := b.clock.Now()
 	for {
 		select {
 		case <-b.ctx.Done():
@@ -226,40 +353,111 @@ func (b *Backoff) run(f Func, retry bool) {
 		}
 		err = f(b.ctx)
 		switch {
-		case
-			// Do is finished when an error has occurred.
-			!retry && err != nil,
+		case retry && err == nil:
 			// Retry is finished when no error occurred.
-			retry && err == nil:
-			// Job done.
+			b.err <- err
+			return
+		case err != nil && !isRetryable(err):
+			// Do and Retry are finished when a non-retryable error occurred.
 			b.err <- err
 			return
 		}
 		// Tries to begin a new iteration.
-		rrr = b.next()
-		if rrr != nil {
+		if rrr := b.next(); rrr != nil {
 			b.err <- newErrRetry(err)
 			return
 		}
-		// Waiting before to run the next iteration.
-		rrr = b.sleep()
+		// Computes the delay before the next iteration, notifies any observer, and waits.
+		d, rrr := b.delay()
 		if rrr != nil {
 			b.err <- newErrRetry(err)
 			return
 		}
+		b.notify(b.Attempt(), b.clock.Now().Sub(start), d, err)
+		b.wait(d)
 	}
 }
 
-// sleep pauses the current goroutine for at least the duration of the interval
-// multiplied by the current Fibonacci value.
+// notify invokes the callback set by WithNotify, if any.
+func (b *Backoff) notify(attempt int, elapsed, next time.Duration, err error) {
+	b.mu.RLock()
+	fn := b.notifyFn
+	b.mu.RUnlock()
+	if fn != nil {
+		fn(attempt, elapsed, next, err)
+	}
+}
+
+// sleep pauses the current goroutine for the duration computed by delay.
 func (b *Backoff) sleep() error {
+	d, err := b.delay()
+	if err != nil {
+		return err
+	}
+	b.wait(d)
+	return nil
+}
+
+// delay computes the duration to wait before the next iteration, based on the current
+// Strategy, defaulting to the Fibonacci suite multiplied by the interval, then applying
+// the max interval cap and the configured jitter.
+func (b *Backoff) delay() (time.Duration, error) {
 	b.mu.Lock()
-	d := b.fib() * b.interval
-	b.mu.Unlock()
+	s := b.strategy
+	var (
+		d  time.Duration
+		ok = true
+	)
+	if s != nil {
+		d, ok = s.Next()
+	} else if f := b.fib(); b.maxInterval > 0 && (f < 0 || f > b.maxInterval/b.interval) {
+		// Past the max interval: clamp before the product can overflow.
+		d = b.maxInterval
+	} else {
+		d = f * b.interval
+	}
 	if d < 0 {
+		// Bound exceeded: detect the overflow before it's masked by the max
+		// interval cap or clamped away by jitterLocked.
+		ok = false
+	}
+	if ok {
+		if b.maxInterval > 0 && d > b.maxInterval {
+			// Cap the delay instead of letting it grow unbounded.
+			d = b.maxInterval
+		}
+		d = b.jitterLocked(d)
+	}
+	b.mu.Unlock()
+	if !ok {
 		// Bound exceeded.
-		return ErrRetry
+		return 0, ErrRetry
 	}
-	time.Sleep(d)
-	return nil
+	return d, nil
+}
+
+// wait pauses the current goroutine for the duration d, using the configured Clock.
+func (b *Backoff) wait(d time.Duration) {
+	b.mu.RLock()
+	clk := b.clock
+	b.mu.RUnlock()
+	clk.Sleep(d)
+}
+
+// jitterLocked adds up to ±jitter (or ±jitterPercent% of d) of random noise to d,
+// without going below zero. b.mu must be held by the caller.
+func (b *Backoff) jitterLocked(d time.Duration) time.Duration {
+	n := b.jitter
+	if b.jitterPercent > 0 {
+		n = time.Duration(uint64(d) * b.jitterPercent / 100)
+	}
+	if n <= 0 {
+		return d
+	}
+	// Noise uniformly distributed in [-n, +n].
+	d += time.Duration(b.rnd.Int63n(int64(2*n+1))) - n
+	if d < 0 {
+		return 0
+	}
+	return d
 }
@@ -53,5 +53,5 @@ var errTask = errors.New("oops")
 
 // Task implements the backoff.Func interface.
 func TaskInErr(context.Context) error {
-	return errTask
+	return backoff.RetryableError(errTask)
 }
@@ -6,6 +6,7 @@ package backoff
 
 import (
 	"context"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -52,13 +53,43 @@ func TestBackoff_Retry(t *testing.T) {
 }
 
 func TestBackoff_Reset(t *testing.T) {
-	bo := New(context.Background()).WithInterval(time.Millisecond)
+	bo := New(context.Background()).WithInterval(time.Millisecond).(*Backoff)
 	are := is.New(t)
 	are.True(bo.interval == time.Millisecond)
 	bo.Reset()
 	are.True(bo.interval == DefaultInterval)
 }
 
+func TestBackoff_jitterLocked(t *testing.T) {
+	const base = time.Second
+	are := is.New(t)
+	bo := New(context.Background()).
+		WithJitter(100 * time.Millisecond).
+		WithRand(rand.New(rand.NewSource(1))).(*Backoff)
+	d := bo.jitterLocked(base)
+	are.True(d >= base-100*time.Millisecond) // mismatch lower bound
+	are.True(d <= base+100*time.Millisecond) // mismatch upper bound
+}
+
+func TestBackoff_jitterLocked_percent(t *testing.T) {
+	const base = time.Second
+	are := is.New(t)
+	bo := New(context.Background()).
+		WithJitterPercent(10).
+		WithRand(rand.New(rand.NewSource(1))).(*Backoff)
+	d := bo.jitterLocked(base)
+	are.True(d >= base-base/10) // mismatch lower bound
+	are.True(d <= base+base/10) // mismatch upper bound
+}
+
+func TestBackoff_WithMaxInterval(t *testing.T) {
+	are := is.New(t)
+	bo := New(context.Background()).WithMaxInterval(time.Millisecond).(*Backoff)
+	for i := 0; i < 10; i++ {
+		are.NoErr(bo.sleep()) // unexpected error
+	}
+}
+
 // void implements the Func interface.
 func void(context.Context) error {
 	return nil
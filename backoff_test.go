@@ -40,7 +40,7 @@ func (t *task) KoUntil(context.Context) error {
 	defer t.stopwatch()
 	t.called++
 	if t.called < t.until {
-		return errRetry
+		return backoff.RetryableError(errRetry)
 	}
 	return nil
 }
@@ -92,6 +92,9 @@ func TestDoN(t *testing.T) {
 	are.Equal(i, job.called)         // mismatch call
 }
 
+// TestDoUntil keeps a real deadline: WithDeadline races its own cancellation goroutine
+// against the clock-driven sleep loop, so swapping in the fake clock here would only
+// replace one non-determinism with another, not make the test faster or more precise.
 func TestDoUntil(t *testing.T) {
 	const d = time.Second
 	var (
@@ -144,6 +147,9 @@ func TestRetryN(t *testing.T) {
 	are.Equal(i, job.called)                   // mismatch call
 }
 
+// TestRetryUntil keeps a real deadline for the same reason as TestDoUntil:
+// WithDeadline's cancellation goroutine races the clock-driven sleep loop, so a
+// fake clock wouldn't make the sequence deterministic, only harder to reason about.
 func TestRetryUntil(t *testing.T) {
 	const (
 		n = 2
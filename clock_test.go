@@ -0,0 +1,67 @@
+// Copyright (c) 2019 Hervé Gouchet. All rights reserved.
+// Use of this source code is governed by the MIT License
+// that can be found in the LICENSE file.
+
+package backoff_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/rvflash/backoff"
+)
+
+// fakeClock implements the backoff.Clock interface without any real wait.
+type fakeClock struct {
+	now     time.Time
+	history []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.history = append(c.history, d)
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.Sleep(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestBackoff_WithClock(t *testing.T) {
+	are := is.New(t)
+	clk := &fakeClock{now: time.Now()}
+	job := newTask(4)
+	n, err := backoff.New(context.Background()).
+		WithClock(clk).
+		WithStrategy(backoff.NewConstant(time.Hour)).
+		Retry(job.KoUntil)
+	are.NoErr(err)                       // unexpected error
+	are.Equal(n, 3)                      // mismatch attempt
+	are.Equal(len(clk.history), 3)       // mismatch number of sleeps
+	are.Equal(clk.history[0], time.Hour) // mismatch interval
+}
+
+func TestBackoff_WithNotify(t *testing.T) {
+	are := is.New(t)
+	var attempts []int
+	clk := &fakeClock{now: time.Now()}
+	job := newTask(3)
+	_, err := backoff.New(context.Background()).
+		WithClock(clk).
+		WithStrategy(backoff.NewConstant(time.Second)).
+		WithNotify(func(attempt int, _, next time.Duration, _ error) {
+			attempts = append(attempts, attempt)
+			are.Equal(next, time.Second) // mismatch next delay
+		}).
+		Retry(job.KoUntil)
+	are.NoErr(err)                   // unexpected error
+	are.Equal(attempts, []int{1, 2}) // mismatch notified attempts
+}
@@ -4,6 +4,8 @@
 
 package backoff
 
+import "errors"
+
 const (
 	errPrefix      = "backoff: "
 	errRetryMsg    = "maximum execution number exhausted"
@@ -49,3 +51,35 @@ func newErrRetry(err error) error {
 	}
 	return ErrRetry
 }
+
+// retryableError marks its embedded error as transient, allowing Do or Retry to try again.
+type retryableError struct {
+	err error
+}
+
+// Error implements the error interface.
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the embedded error.
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// RetryableError wraps err to notify Do and Retry that the failed attempt may be tried again.
+// Any error not wrapped with RetryableError is considered terminal: Do and Retry return it
+// immediately instead of looping until the maximum attempt or the deadline is exceeded.
+// RetryableError returns nil if err is nil.
+func RetryableError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+// isRetryable reports whether err was wrapped with RetryableError.
+func isRetryable(err error) bool {
+	var r *retryableError
+	return errors.As(err, &r)
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2019 Hervé Gouchet. All rights reserved.
+// Use of this source code is governed by the MIT License
+// that can be found in the LICENSE file.
+
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/rvflash/backoff"
+)
+
+func TestRetryableError(t *testing.T) {
+	are := is.New(t)
+	are.Equal(backoff.RetryableError(nil), nil) // mismatch nil
+
+	err := backoff.RetryableError(errRetry)
+	are.True(err != nil)                     // mismatch not nil
+	are.True(errors.Is(err, errRetry))       // mismatch unwrap
+	are.Equal(err.Error(), errRetry.Error()) // mismatch message
+}
+
+func TestRetry_nonRetryable(t *testing.T) {
+	are := is.New(t)
+	job := newTask(0)
+	n, err := backoff.Retry(context.Background(), job.OkUntil)
+	are.Equal(err, errRetry) // mismatch error
+	are.Equal(n, 0)          // mismatch attempt
+	are.Equal(job.called, 1) // mismatch call
+}
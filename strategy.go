@@ -0,0 +1,69 @@
+// Copyright (c) 2019 Hervé Gouchet. All rights reserved.
+// Use of this source code is governed by the MIT License
+// that can be found in the LICENSE file.
+
+package backoff
+
+import "time"
+
+// Strategy computes the delay to wait before the next attempt.
+type Strategy interface {
+	// Next returns the duration to wait before the next attempt.
+	// It returns false once the strategy has no more delay to offer.
+	Next() (time.Duration, bool)
+}
+
+// NewConstant returns a Strategy waiting the same base duration between each attempt.
+func NewConstant(base time.Duration) Strategy {
+	return &constantStrategy{base: base}
+}
+
+type constantStrategy struct {
+	base time.Duration
+}
+
+// Next implements the Strategy interface.
+func (s *constantStrategy) Next() (time.Duration, bool) {
+	return s.base, true
+}
+
+// NewExponential returns a Strategy doubling the base duration at each attempt (base, 2*base, 4*base...).
+func NewExponential(base time.Duration) Strategy {
+	return &exponentialStrategy{base: base}
+}
+
+type exponentialStrategy struct {
+	base    time.Duration
+	attempt uint
+}
+
+// Next implements the Strategy interface.
+func (s *exponentialStrategy) Next() (time.Duration, bool) {
+	d := s.base << s.attempt
+	if d>>s.attempt != s.base {
+		// Bound exceeded: time.Duration wrapped around.
+		return 0, false
+	}
+	s.attempt++
+	return d, true
+}
+
+// NewFibonacci returns a Strategy growing the base duration following the Fibonacci suite.
+func NewFibonacci(base time.Duration) Strategy {
+	return &fibonacciStrategy{base: base, fib: fibonacci()}
+}
+
+type fibonacciStrategy struct {
+	base time.Duration
+	fib  funcAlgorithm
+}
+
+// Next implements the Strategy interface.
+func (s *fibonacciStrategy) Next() (time.Duration, bool) {
+	d := s.fib() * s.base
+	if d < 0 {
+		// Bound exceeded.
+		return 0, false
+	}
+	return d, true
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2019 Hervé Gouchet. All rights reserved.
+// Use of this source code is governed by the MIT License
+// that can be found in the LICENSE file.
+
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/rvflash/backoff"
+)
+
+func TestNewConstant(t *testing.T) {
+	are := is.New(t)
+	s := backoff.NewConstant(time.Second)
+	for i := 0; i < 3; i++ {
+		d, ok := s.Next()
+		are.True(ok)              // mismatch ok
+		are.Equal(d, time.Second) // mismatch duration
+	}
+}
+
+func TestNewExponential(t *testing.T) {
+	are := is.New(t)
+	s := backoff.NewExponential(time.Second)
+	for _, v := range []time.Duration{1, 2, 4, 8} {
+		d, ok := s.Next()
+		are.True(ok)                // mismatch ok
+		are.Equal(d, v*time.Second) // mismatch duration
+	}
+}
+
+func TestNewFibonacci(t *testing.T) {
+	are := is.New(t)
+	s := backoff.NewFibonacci(time.Second)
+	for _, v := range []time.Duration{1, 1, 2, 3, 5, 8} {
+		d, ok := s.Next()
+		are.True(ok)                // mismatch ok
+		are.Equal(d, v*time.Second) // mismatch duration
+	}
+}
+
+func TestBackoff_WithStrategy(t *testing.T) {
+	are := is.New(t)
+	job := newTask(2)
+	n, err := backoff.New(nil).WithStrategy(backoff.NewConstant(time.Millisecond)).Retry(job.KoUntil)
+	are.NoErr(err)  // unexpected error
+	are.Equal(n, 1) // mismatch attempt
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2019 Hervé Gouchet. All rights reserved.
+// Use of this source code is governed by the MIT License
+// that can be found in the LICENSE file.
+
+package backoff
+
+import "time"
+
+// Ticker delivers a tick on C at each step of the Backoff's configured schedule
+// (Strategy or Fibonacci suite, capped and jittered the same way Do and Retry are),
+// decoupling when to retry from what to run.
+type Ticker struct {
+	// C delivers the current time at each tick. It is closed once Stop is called,
+	// the Backoff's context is done, its maximum attempt is reached or no further
+	// delay can be computed.
+	C <-chan time.Time
+
+	stop chan struct{}
+}
+
+// Stop terminates the Ticker. It must be called once the Ticker is no longer needed,
+// to release the underlying goroutine.
+func (t *Ticker) Stop() {
+	close(t.stop)
+}
+
+// Ticker returns a Ticker firing according to the Backoff's configured schedule.
+func (b *Backoff) Ticker() *Ticker {
+	c := make(chan time.Time)
+	t := &Ticker{C: c, stop: make(chan struct{})}
+	if b.fib == nil {
+		close(c)
+		return t
+	}
+	go func() {
+		defer close(c)
+		for {
+			select {
+			case <-b.ctx.Done():
+				return
+			case <-t.stop:
+				return
+			default:
+			}
+			if err := b.next(); err != nil {
+				return
+			}
+			if err := b.sleep(); err != nil {
+				return
+			}
+			select {
+			case c <- b.clock.Now():
+			case <-t.stop:
+				return
+			case <-b.ctx.Done():
+				return
+			}
+		}
+	}()
+	return t
+}
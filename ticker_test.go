@@ -0,0 +1,43 @@
+// Copyright (c) 2019 Hervé Gouchet. All rights reserved.
+// Use of this source code is governed by the MIT License
+// that can be found in the LICENSE file.
+
+package backoff_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/rvflash/backoff"
+)
+
+func TestBackoff_Ticker(t *testing.T) {
+	are := is.New(t)
+	bo := backoff.New(context.Background()).
+		WithClock(&fakeClock{now: time.Now()}).
+		WithStrategy(backoff.NewConstant(time.Millisecond)).
+		WithMaxAttempt(3).(*backoff.Backoff)
+	tk := bo.Ticker()
+	defer tk.Stop()
+
+	var n int
+	for range tk.C {
+		n++
+	}
+	are.Equal(n, 2) // mismatch tick count
+}
+
+func TestBackoff_Ticker_Stop(t *testing.T) {
+	are := is.New(t)
+	bo := backoff.New(context.Background()).
+		WithClock(&fakeClock{now: time.Now()}).
+		WithStrategy(backoff.NewConstant(time.Millisecond)).(*backoff.Backoff)
+	tk := bo.Ticker()
+
+	<-tk.C
+	tk.Stop()
+	_, ok := <-tk.C
+	are.True(!ok) // expected ticker to stop delivering after Stop
+}